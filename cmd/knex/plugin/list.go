@@ -0,0 +1,32 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	spfviper "github.com/spf13/viper"
+)
+
+func newListCommand(config *spfviper.Viper) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List plugins cached in the local plugin directory",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return list(cmd.OutOrStdout(), config.GetString("plugin-dir"))
+		},
+	}
+}
+
+func list(out io.Writer, dir string) error {
+	entries, err := readIndex(dir)
+	if err != nil {
+		return fmt.Errorf("unable to read plugin index: %w", err)
+	}
+
+	for _, e := range entries {
+		fmt.Fprintf(out, "%s\t%s\t%s\n", e.Name, e.Ref, e.Digest)
+	}
+
+	return nil
+}
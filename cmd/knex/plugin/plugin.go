@@ -0,0 +1,47 @@
+// Package plugin implements knex's "plugin" subcommand, which manages
+// certification-check plugins that are distributed as OCI artifacts rather
+// than compiled into the knex binary.
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	spfviper "github.com/spf13/viper"
+)
+
+// NewCommand builds the "plugin" command tree: pull, list, and remove.
+func NewCommand(ctx context.Context, config *spfviper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "Pull, list, and remove knex certification-check plugins",
+	}
+
+	cmd.PersistentFlags().String("plugin-dir", "", "Where plugin artifacts are cached locally. (env: PFLT_PLUGIN_DIR)")
+	_ = config.BindPFlag("plugin-dir", cmd.PersistentFlags().Lookup("plugin-dir"))
+	config.SetDefault("plugin-dir", DefaultPluginDir())
+
+	cmd.PersistentFlags().String("plugin-pubkey", "", "Path to the cosign public key plugin artifacts must be signed with. Required to pull plugins. (env: PFLT_PLUGIN_PUBKEY)")
+	_ = config.BindPFlag("plugin-pubkey", cmd.PersistentFlags().Lookup("plugin-pubkey"))
+
+	cmd.AddCommand(newPullCommand(ctx, config))
+	cmd.AddCommand(newListCommand(config))
+	cmd.AddCommand(newRemoveCommand(config))
+
+	return cmd
+}
+
+// DefaultPluginDir is used when --plugin-dir/PFLT_PLUGIN_DIR is not
+// supplied. It's exported so "knex run" can default to the same directory
+// "knex plugin pull" caches into, without requiring the user to pass an
+// identical --plugin-dir to both.
+func DefaultPluginDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".knex/plugins"
+	}
+
+	return filepath.Join(home, ".local", "share", "knex", "plugins")
+}
@@ -0,0 +1,201 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/opdev/knex/plugin/v0"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+	spfviper "github.com/spf13/viper"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// goPluginLayerMediaType marks the OCI layer inside a plugin artifact that
+// holds a Go plugin (.so) binary.
+const goPluginLayerMediaType = "application/vnd.knex.plugin.goplugin.v1"
+
+// grpcPluginLayerMediaType marks the OCI layer inside a plugin artifact that
+// holds a standalone executable speaking knex's out-of-process gRPC plugin
+// protocol, the transport plugin/v0/grpc implements.
+const grpcPluginLayerMediaType = "application/vnd.knex.plugin.grpcplugin.v1"
+
+// indexFileName records, inside --plugin-dir, which plugin names map to
+// which cached artifact paths so list/remove and the next "knex run" don't
+// need to re-query the registry.
+const indexFileName = "knex-index.json"
+
+// indexEntry is one row of the local plugin index.
+type indexEntry struct {
+	Name   string `json:"name"`
+	Ref    string `json:"ref"`
+	Digest string `json:"digest"`
+	Path   string `json:"path"`
+	Kind   string `json:"kind"`
+}
+
+func newPullCommand(ctx context.Context, config *spfviper.Viper) *cobra.Command {
+	return &cobra.Command{
+		Use:   "pull <ref>",
+		Short: "Pull a plugin artifact from an OCI registry into the local plugin cache",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return pull(ctx, config.GetString("plugin-dir"), config.GetString("plugin-pubkey"), args[0])
+		},
+	}
+}
+
+// pull fetches ref (an OCI artifact reference, e.g.
+// registry.example.com/knex-plugins/check-widget:v1), verifying its cosign
+// signature against pubKeyPath *before* any content is written to disk,
+// then copies it into dir, records it in the local index, and registers it
+// into plugin.RegisteredPlugins() so it's available the next time "knex run"
+// starts.
+func pull(ctx context.Context, dir, pubKeyPath, ref string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("unable to create plugin dir %s: %w", dir, err)
+	}
+
+	src, err := remote.NewRepository(ref)
+	if err != nil {
+		return fmt.Errorf("invalid plugin reference %s: %w", ref, err)
+	}
+
+	manifestDesc, err := src.Resolve(ctx, ref)
+	if err != nil {
+		return fmt.Errorf("unable to resolve %s: %w", ref, err)
+	}
+
+	// Verify before anything is written to the local store: an unsigned or
+	// badly-signed artifact should never touch disk, let alone get loaded.
+	if err := verifySignature(ctx, ref, manifestDesc.Digest.String(), pubKeyPath); err != nil {
+		return fmt.Errorf("signature verification failed for %s: %w", ref, err)
+	}
+
+	dst, err := oci.New(dir)
+	if err != nil {
+		return fmt.Errorf("unable to open local plugin store %s: %w", dir, err)
+	}
+
+	if _, err := oras.Copy(ctx, src, ref, dst, ref, oras.DefaultCopyOptions); err != nil {
+		return fmt.Errorf("unable to pull %s: %w", ref, err)
+	}
+
+	manifestBytes, err := content.FetchAll(ctx, dst, manifestDesc)
+	if err != nil {
+		return fmt.Errorf("unable to read manifest for %s: %w", ref, err)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return fmt.Errorf("unable to parse manifest for %s: %w", ref, err)
+	}
+
+	layerDesc, kind, err := pluginLayer(manifest)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ref, err)
+	}
+
+	entry := indexEntry{
+		Name:   filepath.Base(withoutTag(ref)),
+		Ref:    ref,
+		Digest: manifestDesc.Digest.String(),
+		Path:   filepath.Join("blobs", layerDesc.Digest.Algorithm().String(), layerDesc.Digest.Encoded()),
+		Kind:   kind,
+	}
+
+	if err := appendIndex(dir, entry); err != nil {
+		return fmt.Errorf("unable to update plugin index: %w", err)
+	}
+
+	return plugin.RegisterFromArtifact(dir, plugin.ArtifactMeta{Name: entry.Name, Path: entry.Path, Kind: entry.Kind})
+}
+
+// pluginLayer finds the layer in manifest that holds the actual plugin
+// artifact and classifies it by the "kind" plugin.RegisterFromArtifact
+// expects, since OCI blobs are content-addressed and carry no file
+// extension to dispatch on.
+func pluginLayer(manifest ocispec.Manifest) (ocispec.Descriptor, string, error) {
+	for _, l := range manifest.Layers {
+		switch l.MediaType {
+		case goPluginLayerMediaType:
+			return l, plugin.ArtifactKindGoPlugin, nil
+		case grpcPluginLayerMediaType:
+			return l, plugin.ArtifactKindGRPCPlugin, nil
+		}
+	}
+
+	return ocispec.Descriptor{}, "", fmt.Errorf("no recognized plugin layer (expected media type %s or %s)", goPluginLayerMediaType, grpcPluginLayerMediaType)
+}
+
+// RegisterCached loads dir's local plugin index and calls
+// plugin.RegisterFromArtifact for every entry in it, so plugins pulled by a
+// prior "knex plugin pull" are available to this "knex run" process too
+// instead of only the one that ran the pull. A missing or empty index is
+// not an error: nothing has been pulled yet, and compiled-in plugins should
+// still run.
+func RegisterCached(dir string) error {
+	entries, err := readIndex(dir)
+	if err != nil {
+		return fmt.Errorf("unable to read plugin index: %w", err)
+	}
+
+	for _, e := range entries {
+		meta := plugin.ArtifactMeta{Name: e.Name, Path: e.Path, Kind: e.Kind}
+		if err := plugin.RegisterFromArtifact(dir, meta); err != nil {
+			return fmt.Errorf("unable to register cached plugin %s: %w", e.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func readIndex(dir string) ([]indexEntry, error) {
+	b, err := os.ReadFile(filepath.Join(dir, indexFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []indexEntry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func writeIndex(dir string, entries []indexEntry) error {
+	b, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, indexFileName), b, 0o644)
+}
+
+// appendIndex adds entry to the local index, replacing any existing entry
+// with the same name so re-pulling a plugin updates it in place.
+func appendIndex(dir string, entry indexEntry) error {
+	entries, err := readIndex(dir)
+	if err != nil {
+		return err
+	}
+
+	for i, e := range entries {
+		if e.Name == entry.Name {
+			entries[i] = entry
+			return writeIndex(dir, entries)
+		}
+	}
+
+	return writeIndex(dir, append(entries, entry))
+}
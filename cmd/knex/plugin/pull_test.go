@@ -0,0 +1,79 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadIndexMissingFile(t *testing.T) {
+	entries, err := readIndex(t.TempDir())
+	if err != nil {
+		t.Fatalf("readIndex returned an error for a missing index: %v", err)
+	}
+	if entries != nil {
+		t.Errorf("entries = %v, want nil", entries)
+	}
+}
+
+func TestWriteIndexThenReadIndex(t *testing.T) {
+	dir := t.TempDir()
+	want := []indexEntry{
+		{Name: "check-widget", Ref: "registry/check-widget:v1", Digest: "sha256:abc", Path: "blobs/sha256/abc", Kind: "goplugin"},
+		{Name: "check-gadget", Ref: "registry/check-gadget:v2", Digest: "sha256:def", Path: "blobs/sha256/def", Kind: "grpcplugin"},
+	}
+
+	if err := writeIndex(dir, want); err != nil {
+		t.Fatalf("writeIndex returned an error: %v", err)
+	}
+
+	got, err := readIndex(dir)
+	if err != nil {
+		t.Fatalf("readIndex returned an error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readIndex(dir) = %+v, want %+v", got, want)
+	}
+}
+
+func TestAppendIndexAddsNewEntry(t *testing.T) {
+	dir := t.TempDir()
+
+	first := indexEntry{Name: "check-widget", Digest: "sha256:abc"}
+	if err := appendIndex(dir, first); err != nil {
+		t.Fatalf("appendIndex returned an error: %v", err)
+	}
+
+	second := indexEntry{Name: "check-gadget", Digest: "sha256:def"}
+	if err := appendIndex(dir, second); err != nil {
+		t.Fatalf("appendIndex returned an error: %v", err)
+	}
+
+	entries, err := readIndex(dir)
+	if err != nil {
+		t.Fatalf("readIndex returned an error: %v", err)
+	}
+	if want := []indexEntry{first, second}; !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %+v, want %+v", entries, want)
+	}
+}
+
+func TestAppendIndexReplacesExistingEntry(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := appendIndex(dir, indexEntry{Name: "check-widget", Digest: "sha256:abc"}); err != nil {
+		t.Fatalf("appendIndex returned an error: %v", err)
+	}
+
+	updated := indexEntry{Name: "check-widget", Digest: "sha256:def"}
+	if err := appendIndex(dir, updated); err != nil {
+		t.Fatalf("appendIndex returned an error: %v", err)
+	}
+
+	entries, err := readIndex(dir)
+	if err != nil {
+		t.Fatalf("readIndex returned an error: %v", err)
+	}
+	if want := []indexEntry{updated}; !reflect.DeepEqual(entries, want) {
+		t.Errorf("re-pulling check-widget should update its entry in place: entries = %+v, want %+v", entries, want)
+	}
+}
@@ -0,0 +1,56 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/opdev/knex/plugin/v0"
+	"github.com/spf13/cobra"
+	spfviper "github.com/spf13/viper"
+)
+
+func newRemoveCommand(config *spfviper.Viper) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a cached plugin artifact and unregister it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return remove(config.GetString("plugin-dir"), args[0])
+		},
+	}
+}
+
+// remove deletes name's cached artifact and index entry, then unregisters it
+// so a later "knex run" in the same process won't still offer it.
+func remove(dir, name string) error {
+	entries, err := readIndex(dir)
+	if err != nil {
+		return fmt.Errorf("unable to read plugin index: %w", err)
+	}
+
+	kept := entries[:0]
+	found := false
+	for _, e := range entries {
+		if e.Name == name {
+			found = true
+			if err := os.Remove(filepath.Join(dir, e.Path)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("unable to remove cached artifact for %s: %w", name, err)
+			}
+			continue
+		}
+		kept = append(kept, e)
+	}
+
+	if !found {
+		return fmt.Errorf("no cached plugin named %q", name)
+	}
+
+	if err := writeIndex(dir, kept); err != nil {
+		return fmt.Errorf("unable to update plugin index: %w", err)
+	}
+
+	plugin.Unregister(name)
+
+	return nil
+}
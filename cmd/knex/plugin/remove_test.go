@@ -0,0 +1,83 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRemoveDeletesArtifactAndIndexEntry(t *testing.T) {
+	dir := t.TempDir()
+
+	widget := indexEntry{Name: "check-widget", Path: "blobs/sha256/abc"}
+	gadget := indexEntry{Name: "check-gadget", Path: "blobs/sha256/def"}
+	if err := writeIndex(dir, []indexEntry{widget, gadget}); err != nil {
+		t.Fatalf("writeIndex returned an error: %v", err)
+	}
+
+	artifactPath := filepath.Join(dir, widget.Path)
+	if err := os.MkdirAll(filepath.Dir(artifactPath), 0o755); err != nil {
+		t.Fatalf("unable to set up fake artifact: %v", err)
+	}
+	if err := os.WriteFile(artifactPath, []byte("fake plugin artifact"), 0o644); err != nil {
+		t.Fatalf("unable to set up fake artifact: %v", err)
+	}
+
+	if err := remove(dir, widget.Name); err != nil {
+		t.Fatalf("remove returned an error: %v", err)
+	}
+
+	if _, err := os.Stat(artifactPath); !os.IsNotExist(err) {
+		t.Errorf("cached artifact %s still exists after remove", artifactPath)
+	}
+
+	entries, err := readIndex(dir)
+	if err != nil {
+		t.Fatalf("readIndex returned an error: %v", err)
+	}
+	if want := []indexEntry{gadget}; !reflect.DeepEqual(entries, want) {
+		t.Errorf("index after remove = %+v, want %+v", entries, want)
+	}
+}
+
+func TestRemoveUnknownPlugin(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeIndex(dir, []indexEntry{{Name: "check-widget"}}); err != nil {
+		t.Fatalf("writeIndex returned an error: %v", err)
+	}
+
+	if err := remove(dir, "check-nonexistent"); err == nil {
+		t.Fatal("remove of an unknown plugin name returned no error")
+	}
+
+	entries, err := readIndex(dir)
+	if err != nil {
+		t.Fatalf("readIndex returned an error: %v", err)
+	}
+	if want := []indexEntry{{Name: "check-widget"}}; !reflect.DeepEqual(entries, want) {
+		t.Errorf("a failed remove should leave the index untouched: entries = %+v, want %+v", entries, want)
+	}
+}
+
+func TestRemoveMissingArtifactFileIsNotAnError(t *testing.T) {
+	dir := t.TempDir()
+	entry := indexEntry{Name: "check-widget", Path: "blobs/sha256/does-not-exist"}
+	if err := writeIndex(dir, []indexEntry{entry}); err != nil {
+		t.Fatalf("writeIndex returned an error: %v", err)
+	}
+
+	// The artifact file was never created, e.g. the cache was already
+	// cleaned up by hand; remove should still drop the stale index entry.
+	if err := remove(dir, entry.Name); err != nil {
+		t.Fatalf("remove returned an error for an already-missing artifact: %v", err)
+	}
+
+	entries, err := readIndex(dir)
+	if err != nil {
+		t.Fatalf("readIndex returned an error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("entries = %+v, want empty", entries)
+	}
+}
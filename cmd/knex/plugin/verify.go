@@ -0,0 +1,62 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+)
+
+// verifySignature checks that ref's cosign signature is present, valid, and
+// made with pubKeyPath's key before its artifact is registered as a plugin,
+// since plugin code runs with the same privileges as knex itself.
+//
+// pubKeyPath is required: knex refuses to pull a plugin when it has no trust
+// anchor to check a signature against, rather than either hard-failing every
+// pull or silently accepting a signature from any Sigstore identity.
+func verifySignature(ctx context.Context, ref, digest, pubKeyPath string) error {
+	if pubKeyPath == "" {
+		return fmt.Errorf("no --plugin-pubkey/PFLT_PLUGIN_PUBKEY configured; refusing to pull an unverifiable plugin")
+	}
+
+	verifier, err := cosign.PublicKeyFromKeyRef(ctx, pubKeyPath)
+	if err != nil {
+		return fmt.Errorf("unable to load plugin public key %s: %w", pubKeyPath, err)
+	}
+
+	dgstRef, err := name.NewDigest(fmt.Sprintf("%s@%s", withoutTag(ref), digest))
+	if err != nil {
+		return fmt.Errorf("unable to resolve digest reference for %s: %w", ref, err)
+	}
+
+	checkOpts := &cosign.CheckOpts{
+		SigVerifier: verifier,
+		IgnoreTlog:  true,
+		IgnoreSCT:   true,
+	}
+
+	if _, _, err := cosign.VerifyImageSignatures(ctx, dgstRef, checkOpts); err != nil {
+		return fmt.Errorf("no valid signature found for %s: %w", dgstRef, err)
+	}
+
+	return nil
+}
+
+// withoutTag strips a trailing ":tag" or "@digest" from ref so it can be
+// recombined with a (possibly different) digest, since an OCI reference
+// can't carry a tag and a digest at once. The "@" check has to come first:
+// a digest's own "sha256:..." contains a ":" too, so a digest-pinned ref
+// would otherwise have its digest mistaken for a tag and truncated mid-hash.
+func withoutTag(ref string) string {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		return ref[:i]
+	}
+
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i]
+	}
+
+	return ref
+}
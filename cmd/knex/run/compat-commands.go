@@ -23,17 +23,23 @@ func NewBackwardsCompatCheckCommand() *cobra.Command {
 
 	cmd.PersistentFlags().String("logfile", "", "Where the execution logfile will be written. (env: PFLT_LOGFILE)")
 	cmd.PersistentFlags().String("loglevel", "", "The verbosity of the preflight tool itself. Ex. warn, debug, trace, info, error. (env: PFLT_LOGLEVEL)")
+	cmd.PersistentFlags().String("logformat", "", "The format for log output. One of text, color, json. (env: PFLT_LOGFORMAT)")
 	cmd.PersistentFlags().String("artifacts", "", "Where check-specific artifacts will be written. (env: PFLT_ARTIFACTS)")
+	cmd.PersistentFlags().String("format", "", "The format results should be written in. One of text, json, xml, junitxml. (env: PFLT_FORMAT)")
 	cmd.PersistentFlags().BoolP("submit", "s", false, "Submit results to Red Hat if the called plugin supports it automated submission through this tool.")
 
 	containerConfig := spfviper.New()
 	_ = containerConfig.BindPFlag("logfile", cmd.PersistentFlags().Lookup("logfile"))
 	_ = containerConfig.BindPFlag("loglevel", cmd.PersistentFlags().Lookup("loglevel"))
+	_ = containerConfig.BindPFlag("logformat", cmd.PersistentFlags().Lookup("logformat"))
 	_ = containerConfig.BindPFlag("artifacts", cmd.PersistentFlags().Lookup("artifacts"))
+	_ = containerConfig.BindPFlag("format", cmd.PersistentFlags().Lookup("format"))
 	_ = containerConfig.BindPFlag("submit", cmd.PersistentFlags().Lookup("submit"))
 	containerConfig.SetDefault("logfile", DefaultLogFile)
 	containerConfig.SetDefault("loglevel", DefaultLogLevel)
+	containerConfig.SetDefault("logformat", DefaultLogFormat)
 	containerConfig.SetDefault("artifacts", artifacts.DefaultArtifactsDir)
+	containerConfig.SetDefault("format", DefaultFormat)
 	containerConfig.SetDefault("submit", false)
 
 	// Build out the Container Plugin
@@ -0,0 +1,80 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/opdev/knex/plugin/v0"
+	"github.com/opdev/knex/plugin/v0/grpc"
+)
+
+// discoverGRPCPlugins scans dir for executable files, launches each as an
+// out-of-process plugin, and registers it into plugin.RegisteredPlugins()
+// keyed by its filename, where "knex run all" runs it alongside the
+// compiled-in plugins. It runs from the run command's PersistentPreRunE,
+// after NewCommand has already built one subcommand per plugin registered
+// at that point, so a discovered plugin gets no "knex run <name>"
+// subcommand of its own. It also returns every client it launched, keyed
+// the same way, so the caller can close the ones that turn out not to be used instead of
+// leaking their child processes for the life of the parent.
+func discoverGRPCPlugins(dir string) (map[string]*grpc.Client, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read plugin dir %s: %w", dir, err)
+	}
+
+	clients := make(map[string]*grpc.Client)
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil || info.Mode()&0o111 == 0 {
+			continue
+		}
+
+		path := filepath.Join(dir, e.Name())
+		client, err := grpc.NewGRPCClient(path)
+		if err != nil {
+			closeGRPCClients(clients)
+			return nil, fmt.Errorf("unable to register plugin %s: %w", path, err)
+		}
+
+		clients[e.Name()] = client
+		plugin.RegisteredPlugins()[e.Name()] = client
+	}
+
+	return clients, nil
+}
+
+// closeGRPCClients stops every discovered client's child process.
+func closeGRPCClients(clients map[string]*grpc.Client) {
+	for _, c := range clients {
+		c.Close()
+	}
+}
+
+// closeUnusedGRPCClients stops every discovered client's child process
+// except keep's, since only one plugin subcommand ever runs per "knex run"
+// invocation.
+func closeUnusedGRPCClients(clients map[string]*grpc.Client, keep string) {
+	for name, c := range clients {
+		if name != keep {
+			c.Close()
+		}
+	}
+}
+
+// closeGRPCClient stops name's child process, if it was one of the
+// discovered gRPC plugins, once that plugin has finished running.
+func closeGRPCClient(clients map[string]*grpc.Client, name string) {
+	if c, ok := clients[name]; ok {
+		c.Close()
+	}
+}
@@ -0,0 +1,134 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/opdev/knex/plugin/v0"
+	"github.com/opdev/knex/types"
+)
+
+// DefaultFormat is used when --format/PFLT_FORMAT is not supplied.
+const DefaultFormat = "text"
+
+// FormatterFunc is an alias for plugin.FormatterFunc, kept so existing code
+// in this package doesn't need a plugin.-qualified reference at every call
+// site. The registry itself lives in plugin/v0 so plugin authors can call
+// plugin.RegisterFormatter without depending on this command-layer package.
+type FormatterFunc = plugin.FormatterFunc
+
+func init() {
+	mustRegisterFormatter("text", "txt", formatAsText)
+	mustRegisterFormatter("json", "json", formatAsJSON)
+	mustRegisterFormatter("xml", "xml", formatAsXML)
+	mustRegisterFormatter("junitxml", "xml", formatAsJUnitXML)
+}
+
+// mustRegisterFormatter registers one of knex's built-in formatters. A
+// failure here means two built-ins collide on name, which is a programming
+// error worth panicking over rather than silently dropping a format.
+func mustRegisterFormatter(name, ext string, fn FormatterFunc) {
+	if err := plugin.RegisterFormatter(name, ext, fn); err != nil {
+		panic(err)
+	}
+}
+
+// formatterFor resolves the FormatterFunc and results-file extension
+// registered under name.
+func formatterFor(name string) (FormatterFunc, string, error) {
+	return plugin.FormatterFor(name)
+}
+
+// formatAsJSON renders results as a single indented JSON document.
+var formatAsJSON FormatterFunc = func(_ context.Context, r types.Results) (response []byte, formattingError error) {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal results as json: %w", err)
+	}
+
+	return b, nil
+}
+
+// formatAsXML renders results as an XML document mirroring the JSON shape.
+var formatAsXML FormatterFunc = func(_ context.Context, r types.Results) (response []byte, formattingError error) {
+	b, err := xml.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal results as xml: %w", err)
+	}
+
+	return append([]byte(xml.Header), b...), nil
+}
+
+// junitTestSuite and junitTestCase are sized from types.Results' Passed,
+// Failed, and Errors so CI systems (Jenkins, Tekton, GitHub Actions) that
+// consume JUnit XML natively can report knex's checks without translation.
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Time    string        `xml:"time,attr"`
+	Failure *junitMessage `xml:"failure,omitempty"`
+	Error   *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// formatAsJUnitXML renders results as a single JUnit <testsuite>, with each
+// check becoming a <testcase> and failed/errored checks getting a <failure>
+// or <error> element respectively.
+var formatAsJUnitXML FormatterFunc = func(_ context.Context, r types.Results) (response []byte, formattingError error) {
+	suite := junitTestSuite{
+		Name:     "knex",
+		Tests:    len(r.Passed) + len(r.Failed) + len(r.Errors),
+		Failures: len(r.Failed),
+		Errors:   len(r.Errors),
+	}
+
+	for _, v := range r.Passed {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name: v.Name(),
+			Time: fmt.Sprintf("%.3f", v.ElapsedTime.Seconds()),
+		})
+	}
+
+	for _, v := range r.Failed {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name: v.Name(),
+			Time: fmt.Sprintf("%.3f", v.ElapsedTime.Seconds()),
+			Failure: &junitMessage{
+				Message: "check failed",
+				Body:    v.Name(),
+			},
+		})
+	}
+
+	for _, v := range r.Errors {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name: v.Name(),
+			Time: fmt.Sprintf("%.3f", v.ElapsedTime.Seconds()),
+			Error: &junitMessage{
+				Message: "check errored",
+				Body:    v.Name(),
+			},
+		})
+	}
+
+	b, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal results as junit xml: %w", err)
+	}
+
+	return append([]byte(xml.Header), b...), nil
+}
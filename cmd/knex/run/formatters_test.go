@@ -0,0 +1,86 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/opdev/knex/types"
+)
+
+// These exercise the built-in formatters against an empty types.Results.
+// types.Results itself isn't defined in this tree (it's an external
+// package), so its own JSON/XML field encoding isn't knex's to golden-test
+// here; formatAsJUnitXML's testsuite/testcase shape is entirely ours,
+// though, so that one gets an exact golden comparison.
+
+func TestFormatAsJSON(t *testing.T) {
+	b, err := formatAsJSON(context.Background(), types.Results{})
+	if err != nil {
+		t.Fatalf("formatAsJSON returned an error: %v", err)
+	}
+
+	var v map[string]any
+	if err := json.Unmarshal(b, &v); err != nil {
+		t.Fatalf("formatAsJSON did not produce a JSON object: %v\noutput: %s", err, b)
+	}
+}
+
+func TestFormatAsXML(t *testing.T) {
+	b, err := formatAsXML(context.Background(), types.Results{})
+	if err != nil {
+		t.Fatalf("formatAsXML returned an error: %v", err)
+	}
+
+	if !strings.HasPrefix(string(b), xml.Header) {
+		t.Fatalf("formatAsXML output is missing the XML header: %s", b)
+	}
+
+	if err := xml.Unmarshal(b, new(any)); err != nil {
+		t.Fatalf("formatAsXML did not produce well-formed XML: %v\noutput: %s", err, b)
+	}
+}
+
+func TestFormatAsJUnitXMLEmpty(t *testing.T) {
+	b, err := formatAsJUnitXML(context.Background(), types.Results{})
+	if err != nil {
+		t.Fatalf("formatAsJUnitXML returned an error: %v", err)
+	}
+
+	want := xml.Header + `<testsuite name="knex" tests="0" failures="0" errors="0"></testsuite>`
+	if got := string(b); got != want {
+		t.Errorf("formatAsJUnitXML(empty) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestJunitTestSuiteShape(t *testing.T) {
+	// formatAsJUnitXML builds this struct directly from types.Results, but
+	// the XML shape itself only depends on junitTestSuite/junitTestCase, so
+	// those can be golden-tested without a types.Results value at all.
+	suite := junitTestSuite{
+		Name:     "knex",
+		Tests:    2,
+		Failures: 1,
+		TestCases: []junitTestCase{
+			{Name: "a", Time: "0.001"},
+			{Name: "b", Time: "0.002", Failure: &junitMessage{Message: "check failed", Body: "b"}},
+		},
+	}
+
+	b, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		t.Fatalf("xml.MarshalIndent returned an error: %v", err)
+	}
+
+	want := `<testsuite name="knex" tests="2" failures="1" errors="0">
+  <testcase name="a" time="0.001"></testcase>
+  <testcase name="b" time="0.002">
+    <failure message="check failed">b</failure>
+  </testcase>
+</testsuite>`
+	if got := string(b); got != want {
+		t.Errorf("junitTestSuite XML =\n%s\nwant\n%s", got, want)
+	}
+}
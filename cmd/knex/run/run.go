@@ -9,7 +9,9 @@ import (
 
 	"github.com/bombsimon/logrusr/v4"
 	"github.com/go-logr/logr"
+	cmdplugin "github.com/opdev/knex/cmd/knex/plugin"
 	"github.com/opdev/knex/plugin/v0"
+	"github.com/opdev/knex/plugin/v0/grpc"
 	"github.com/opdev/knex/types"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
 	"github.com/sirupsen/logrus"
@@ -18,8 +20,9 @@ import (
 )
 
 const (
-	DefaultLogFile  = "preflight.log"
-	DefaultLogLevel = "info"
+	DefaultLogFile   = ""
+	DefaultLogLevel  = "info"
+	DefaultLogFormat = "text"
 )
 
 func NewCommand(
@@ -36,21 +39,72 @@ func NewCommand(
 	cmd.PersistentFlags().String("loglevel", "", "The verbosity of the preflight tool itself. Ex. warn, debug, trace, info, error. (env: PFLT_LOGLEVEL)")
 	_ = config.BindPFlag("loglevel", cmd.PersistentFlags().Lookup("loglevel"))
 
+	cmd.PersistentFlags().String("logformat", "", "The format for log output. One of text, color, json. (env: PFLT_LOGFORMAT)")
+	_ = config.BindPFlag("logformat", cmd.PersistentFlags().Lookup("logformat"))
+
 	cmd.PersistentFlags().String("artifacts", "", "Where check-specific artifacts will be written. (env: PFLT_ARTIFACTS)")
 	_ = config.BindPFlag("artifacts", cmd.PersistentFlags().Lookup("artifacts"))
 
+	cmd.PersistentFlags().String("format", "", "The format results should be written in. One of text, json, xml, junitxml. (env: PFLT_FORMAT)")
+	_ = config.BindPFlag("format", cmd.PersistentFlags().Lookup("format"))
+
+	cmd.PersistentFlags().String("plugin-dir", "", "Where out-of-process plugin executables are discovered. (env: PFLT_PLUGIN_DIR)")
+	_ = config.BindPFlag("plugin-dir", cmd.PersistentFlags().Lookup("plugin-dir"))
+
 	config.SetDefault("logfile", DefaultLogFile)
 	config.SetDefault("loglevel", DefaultLogLevel)
+	config.SetDefault("logformat", DefaultLogFormat)
 	config.SetDefault("artifacts", artifacts.DefaultArtifactsDir)
+	config.SetDefault("format", DefaultFormat)
+	config.SetDefault("plugin-dir", cmdplugin.DefaultPluginDir())
+
+	// Out-of-process plugins are discovered best-effort: a missing or empty
+	// plugin dir shouldn't prevent compiled-in plugins from running. This
+	// can't happen here in NewCommand: the command tree is built, and this
+	// func runs, before cmd.Execute() parses the user's --plugin-dir flag,
+	// so config would only ever see the default above. PersistentPreRunE
+	// runs once flags are bound, right before whichever plugin subcommand
+	// the user invoked.
+	var grpcClients map[string]*grpc.Client
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		dir := config.GetString("plugin-dir")
+		if dir == "" {
+			return nil
+		}
+
+		// Pick up whatever "knex plugin pull" already cached in this dir in
+		// an earlier, separate process, so it doesn't only exist in memory
+		// for the lifetime of the pull invocation.
+		if err := cmdplugin.RegisterCached(dir); err != nil {
+			cmd.PrintErrln(err)
+		}
+
+		clients, err := discoverGRPCPlugins(dir)
+		if err != nil {
+			cmd.PrintErrln(err)
+		}
+		grpcClients = clients
+
+		return nil
+	}
 
 	for plinvoke, pl := range plugin.RegisteredPlugins() {
+		plinvoke := plinvoke
 		plcmd := plugin.NewCommand(ctx, config, plinvoke, pl)
 		plcmd.RunE = func(cmd *cobra.Command, args []string) error {
+			// plinvoke is the only plugin this invocation can run, so every
+			// other discovered gRPC plugin's child process is dead weight:
+			// close them now instead of leaking them for the life of this
+			// process, and close plinvoke's once run() returns.
+			closeUnusedGRPCClients(grpcClients, plinvoke)
+			defer closeGRPCClient(grpcClients, plinvoke)
 			return run(args, ctx, plinvoke, config, &types.ResultWriterFile{})
 		}
 		cmd.AddCommand(plcmd)
 	}
 
+	cmd.AddCommand(newRunAllCommand(ctx, config))
+
 	return cmd
 }
 
@@ -69,16 +123,30 @@ func run(
 	// stuffing the logger and artifacts writer in the context to maintain
 	// compatibility with the existing container/operator certification.
 	l := logrus.New()
-	l.SetFormatter(&logrus.TextFormatter{DisableColors: true})
 
-	logname := config.GetString("logfile")
-	logFile, err := os.OpenFile(logname, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
-	if err == nil {
-		mw := io.MultiWriter(os.Stderr, logFile)
-		l.SetOutput(mw)
-		defer logFile.Close()
-	} else {
-		l.Infof("Failed to log to file, using default stderr")
+	switch config.GetString("logformat") {
+	case "json":
+		l.SetFormatter(&logrus.JSONFormatter{})
+	case "color":
+		l.SetFormatter(&logrus.TextFormatter{ForceColors: true})
+	default:
+		l.SetFormatter(&logrus.TextFormatter{DisableColors: true})
+	}
+
+	switch logname := config.GetString("logfile"); logname {
+	case "-":
+		l.SetOutput(os.Stdout)
+	case "":
+		l.SetOutput(os.Stderr)
+	default:
+		logFile, err := os.OpenFile(logname, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err == nil {
+			mw := io.MultiWriter(os.Stderr, logFile)
+			l.SetOutput(mw)
+			defer logFile.Close()
+		} else {
+			l.Infof("Failed to log to file, using default stderr")
+		}
 	}
 	if ll, err := logrus.ParseLevel(config.GetString("loglevel")); err == nil {
 		l.SetLevel(ll)
@@ -101,9 +169,15 @@ func run(
 	config.SetEnvKeyReplacer(strings.NewReplacer(`-`, `_`))
 
 	// Writing Results, also borrowed from Preflight (RunPreflight, specifically)
-	// Fail early if we cannot write to the results path.
-	// TODO(Jose): The preflight version of this handles formatters, etc. Stubbed this out to .txt for PoC
-	resultsFilePath, err := artifactsWriter.WriteFile("results.txt", strings.NewReader(""))
+	// Fail early if we cannot write to the results path, and fail early if the
+	// requested --format isn't registered.
+	formatter, resultsExt, err := formatterFor(config.GetString("format"))
+	if err != nil {
+		logger.Error(err, "unable to resolve formatter")
+		return err
+	}
+
+	resultsFilePath, err := artifactsWriter.WriteFile(fmt.Sprintf("results.%s", resultsExt), strings.NewReader(""))
 	if err != nil {
 		return err
 	}
@@ -114,7 +188,14 @@ func run(
 	}
 
 	defer resultsFile.Close()
-	resultsOutputTarget := io.MultiWriter(os.Stdout, resultsFile)
+
+	// --logfile - already sends every log line to stdout; also duplicating
+	// results there would interleave the two on the same stream and break
+	// the machine-parsing use case --format/--logformat json are for.
+	resultsOutputTarget := io.Writer(resultsFile)
+	if config.GetString("logfile") != "-" {
+		resultsOutputTarget = io.MultiWriter(os.Stdout, resultsFile)
+	}
 
 	// Run the plugin
 	plugin := plugin.RegisteredPlugins()[pluginName]
@@ -131,15 +212,15 @@ func run(
 	}
 
 	results := plugin.Results(ctx)
-	textResults, err := formatAsText(ctx, results)
+	formattedResults, err := formatter(ctx, results)
 	if err != nil {
 		logger.Error(err, "unable to format results")
 		return err
 	}
 
-	_, err = resultsOutputTarget.Write(textResults)
+	_, err = resultsOutputTarget.Write(formattedResults)
 	if err != nil {
-		logger.Error(err, "unable to write text results")
+		logger.Error(err, "unable to write formatted results")
 	}
 
 	if config.GetBool("submit") {
@@ -152,9 +233,8 @@ func run(
 	return nil
 }
 
-type FormatterFunc = func(context.Context, types.Results) (response []byte, formattingError error)
-
-// Just as poc formatter, borrowed from preflight's library docs
+// formatAsText is knex's plain-text formatter, registered under "text" in
+// formatters.go's init().
 var formatAsText FormatterFunc = func(_ context.Context, r types.Results) (response []byte, formattingError error) {
 	b := []byte{}
 	for _, v := range r.Passed {
@@ -0,0 +1,224 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bombsimon/logrusr/v4"
+	"github.com/go-logr/logr"
+	"github.com/opdev/knex/plugin/v0"
+	"github.com/opdev/knex/types"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	spfviper "github.com/spf13/viper"
+)
+
+// DefaultConcurrency is used when --concurrency/PFLT_CONCURRENCY is not
+// supplied.
+const DefaultConcurrency = 4
+
+// newRunAllCommand builds "knex run all", which executes every registered
+// plugin concurrently and merges their results into a single results.<ext>
+// artifact, with each plugin's check-specific artifacts kept in its own
+// subdirectory.
+func newRunAllCommand(ctx context.Context, config *spfviper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "all",
+		Short: "Run every registered plugin and aggregate their results",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAll(ctx, config)
+		},
+	}
+
+	cmd.Flags().Int("concurrency", 0, "How many plugins to run at once. (env: PFLT_CONCURRENCY)")
+	_ = config.BindPFlag("concurrency", cmd.Flags().Lookup("concurrency"))
+	config.SetDefault("concurrency", DefaultConcurrency)
+
+	return cmd
+}
+
+// pluginOutcome is one plugin's contribution to a "run all" invocation.
+type pluginOutcome struct {
+	name    string
+	results types.Results
+	err     error
+}
+
+// runAll executes every plugin in plugin.RegisteredPlugins() with at most
+// config's "concurrency" running at once. A failure in one plugin is
+// collected rather than aborting the others; a combined non-zero error is
+// only returned once every plugin has finished.
+func runAll(ctx context.Context, config *spfviper.Viper) error {
+	l := logrus.New()
+	switch config.GetString("logformat") {
+	case "json":
+		l.SetFormatter(&logrus.JSONFormatter{})
+	case "color":
+		l.SetFormatter(&logrus.TextFormatter{ForceColors: true})
+	default:
+		l.SetFormatter(&logrus.TextFormatter{DisableColors: true})
+	}
+	if ll, err := logrus.ParseLevel(config.GetString("loglevel")); err == nil {
+		l.SetLevel(ll)
+	}
+	logger := logrusr.New(l)
+
+	formatter, resultsExt, err := formatterFor(config.GetString("format"))
+	if err != nil {
+		logger.Error(err, "unable to resolve formatter")
+		return err
+	}
+
+	concurrency := config.GetInt("concurrency")
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+
+	registered := plugin.RegisteredPlugins()
+	names := make([]string, 0, len(registered))
+	for name := range registered {
+		names = append(names, name)
+	}
+
+	sem := make(chan struct{}, concurrency)
+	outcomes := make([]pluginOutcome, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			outcomes[i] = runOne(ctx, name, registered[name], config, logger)
+		}(i, name)
+	}
+	wg.Wait()
+
+	combined, failed := mergeOutcomes(outcomes)
+
+	failedPlugins := make([]string, 0, len(failed))
+	for _, o := range failed {
+		logger.Error(o.err, "plugin failed", "plugin", o.name)
+		failedPlugins = append(failedPlugins, o.name)
+	}
+
+	formattedResults, err := formatter(ctx, combined)
+	if err != nil {
+		return fmt.Errorf("unable to format combined results: %w", err)
+	}
+
+	artifactsWriter, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(config.GetString("artifacts")))
+	if err != nil {
+		return err
+	}
+
+	resultsFilePath, err := artifactsWriter.WriteFile(fmt.Sprintf("results.%s", resultsExt), strings.NewReader(""))
+	if err != nil {
+		return err
+	}
+
+	resultsFile, err := (&types.ResultWriterFile{}).OpenFile(resultsFilePath)
+	if err != nil {
+		return err
+	}
+	defer resultsFile.Close()
+
+	if _, err := resultsFile.Write(formattedResults); err != nil {
+		return fmt.Errorf("unable to write combined results: %w", err)
+	}
+
+	if len(failedPlugins) > 0 {
+		return fmt.Errorf("%d plugin(s) failed: %s", len(failedPlugins), strings.Join(failedPlugins, ", "))
+	}
+
+	return nil
+}
+
+// mergeOutcomes splits outcomes into a single combined Results, built from
+// every outcome that didn't error, and the outcomes that did, in the order
+// runAll's names slice produced them.
+func mergeOutcomes(outcomes []pluginOutcome) (types.Results, []pluginOutcome) {
+	var combined types.Results
+	var failed []pluginOutcome
+
+	for _, o := range outcomes {
+		if o.err != nil {
+			failed = append(failed, o)
+			continue
+		}
+
+		combined.Passed = append(combined.Passed, o.results.Passed...)
+		combined.Failed = append(combined.Failed, o.results.Failed...)
+		combined.Errors = append(combined.Errors, o.results.Errors...)
+	}
+
+	return combined, failed
+}
+
+// pluginConfig returns a private copy of config for a single runOne call.
+// config is shared across every concurrently-running plugin, but
+// Plugin.Init mutates the *viper.Viper it's handed, so handing out the same
+// instance would race; copying the settings into a fresh Viper keeps each
+// plugin's mutations local to itself.
+func pluginConfig(config *spfviper.Viper) *spfviper.Viper {
+	pc := spfviper.New()
+	for k, v := range config.AllSettings() {
+		pc.Set(k, v)
+	}
+	return pc
+}
+
+// runOne executes a single plugin under its own artifacts subdirectory and a
+// logger tagged with plugin=<name>, so interleaved concurrent logs stay
+// attributable to the plugin that emitted them.
+func runOne(ctx context.Context, name string, pl plugin.Plugin, config *spfviper.Viper, logger logr.Logger) pluginOutcome {
+	// Out-of-process plugins hold a child process open until closed; every
+	// other plugin kind is a no-op here.
+	if closer, ok := pl.(interface{ Close() }); ok {
+		defer closer.Close()
+	}
+
+	pluginLogger := logger.WithValues("emitter", "plugin", "plugin", name)
+	pluginCtx := logr.NewContext(ctx, pluginLogger)
+
+	pluginArtifactsDir := filepath.Join(config.GetString("artifacts"), name)
+	pluginArtifactsWriter, err := artifacts.NewFilesystemWriter(
+		artifacts.WithDirectory(pluginArtifactsDir),
+	)
+	if err != nil {
+		return pluginOutcome{name: name, err: fmt.Errorf("unable to create artifacts dir for %s: %w", name, err)}
+	}
+	pluginCtx = artifacts.ContextWithWriter(pluginCtx, pluginArtifactsWriter)
+
+	// Plugin.Init mutates the *viper.Viper it's given (see the comment in
+	// run()), and runOne is invoked concurrently across plugins, so each call
+	// needs its own copy rather than sharing config across goroutines. The
+	// copy's "artifacts" points at this plugin's subdirectory, since that's
+	// the path an out-of-process plugin is handed in its Init request.
+	pc := pluginConfig(config)
+	pc.Set("artifacts", pluginArtifactsDir)
+	if err := pl.Init(pluginCtx, pc, nil); err != nil {
+		return pluginOutcome{name: name, err: fmt.Errorf("unable to initialize %s: %w", name, err)}
+	}
+
+	if err := pl.ExecuteChecks(pluginCtx); err != nil {
+		return pluginOutcome{name: name, err: fmt.Errorf("unable to execute checks for %s: %w", name, err)}
+	}
+
+	results := pl.Results(pluginCtx)
+
+	if config.GetBool("submit") {
+		if err := pl.Submit(pluginCtx); err != nil {
+			return pluginOutcome{name: name, results: results, err: fmt.Errorf("unable to submit %s: %w", name, err)}
+		}
+	}
+
+	return pluginOutcome{name: name, results: results}
+}
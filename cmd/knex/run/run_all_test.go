@@ -0,0 +1,83 @@
+package run
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/opdev/knex/types"
+	spfviper "github.com/spf13/viper"
+)
+
+func TestPluginConfigCopiesSettings(t *testing.T) {
+	config := spfviper.New()
+	config.Set("format", "json")
+	config.Set("artifacts", "/tmp/artifacts")
+
+	pc := pluginConfig(config)
+
+	if got, want := pc.GetString("format"), "json"; got != want {
+		t.Errorf("pluginConfig(config).GetString(\"format\") = %q, want %q", got, want)
+	}
+	if got, want := pc.GetString("artifacts"), "/tmp/artifacts"; got != want {
+		t.Errorf("pluginConfig(config).GetString(\"artifacts\") = %q, want %q", got, want)
+	}
+}
+
+func TestPluginConfigIsIndependentOfSource(t *testing.T) {
+	config := spfviper.New()
+	config.Set("format", "json")
+
+	pc := pluginConfig(config)
+
+	// A plugin's Init is free to mutate the copy it's handed; that must not
+	// be visible on config, which every other concurrently-running plugin
+	// is handed its own copy of.
+	pc.Set("format", "xml")
+	if got, want := config.GetString("format"), "json"; got != want {
+		t.Errorf("mutating the copy changed the source: config.GetString(\"format\") = %q, want %q", got, want)
+	}
+
+	// Nor should config changing after the copy was made retroactively
+	// affect a copy already handed to a running plugin.
+	config.Set("format", "junitxml")
+	if got, want := pc.GetString("format"), "xml"; got != want {
+		t.Errorf("mutating the source after copying changed the copy: pc.GetString(\"format\") = %q, want %q", got, want)
+	}
+}
+
+func TestMergeOutcomesCollectsFailuresInOrder(t *testing.T) {
+	errA := errors.New("plugin a failed")
+	errC := errors.New("plugin c failed")
+
+	outcomes := []pluginOutcome{
+		{name: "a", err: errA},
+		{name: "b"},
+		{name: "c", err: errC},
+	}
+
+	_, failed := mergeOutcomes(outcomes)
+
+	if len(failed) != 2 {
+		t.Fatalf("len(failed) = %d, want 2", len(failed))
+	}
+	if failed[0].name != "a" || failed[0].err != errA {
+		t.Errorf("failed[0] = %+v, want name a, err errA", failed[0])
+	}
+	if failed[1].name != "c" || failed[1].err != errC {
+		t.Errorf("failed[1] = %+v, want name c, err errC", failed[1])
+	}
+}
+
+func TestMergeOutcomesNoFailures(t *testing.T) {
+	outcomes := []pluginOutcome{{name: "a"}, {name: "b"}}
+
+	combined, failed := mergeOutcomes(outcomes)
+
+	if failed != nil {
+		t.Errorf("failed = %v, want nil", failed)
+	}
+	if want := (types.Results{}); !reflect.DeepEqual(combined, want) {
+		t.Errorf("combined = %+v, want %+v", combined, want)
+	}
+}
@@ -0,0 +1,152 @@
+// Package lib exposes a Runner type for embedding knex in other Go programs
+// (operator-sdk, tekton tasks, custom controllers) without going through
+// knex's cobra command tree or constructing a *viper.Viper by hand.
+package lib
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/bombsimon/logrusr/v4"
+	"github.com/go-logr/logr"
+	"github.com/opdev/knex/plugin/v0"
+	"github.com/opdev/knex/types"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/sirupsen/logrus"
+	spfviper "github.com/spf13/viper"
+)
+
+// Config holds everything a Runner needs to execute a plugin. Unlike the
+// cobra/viper plumbing in cmd/knex/run, every field here is typed so callers
+// don't need to know knex's viper keys.
+type Config struct {
+	// ArtifactsDir is where check-specific artifacts will be written.
+	ArtifactsDir string
+
+	// LogWriter receives the plugin's log output. A nil LogWriter discards logs.
+	LogWriter io.Writer
+
+	// LogLevel is parsed with logrus.ParseLevel; an empty or invalid value
+	// falls back to logrus's default level.
+	LogLevel string
+
+	// Submit asks the plugin to submit its results after checks complete, if
+	// the plugin supports it.
+	Submit bool
+
+	// PluginArgs are passed through to the plugin's Init call unmodified,
+	// mirroring the positional args a cobra command would hand it.
+	PluginArgs []string
+
+	pluginName string
+}
+
+// Option customizes a Runner after its Config has been applied.
+type Option func(*Runner)
+
+// WithLogger overrides the logr.Logger a Runner passes to its plugin instead
+// of building one from Config.LogWriter/Config.LogLevel. Embedders that
+// already run their own logging (e.g. a controller-runtime logger inside an
+// operator) can pass it straight through rather than feeding it to a second,
+// independently configured logrus instance.
+func WithLogger(logger logr.Logger) Option {
+	return func(r *Runner) {
+		r.logger = &logger
+	}
+}
+
+// Runner executes a single registered plugin outside of knex's cobra command
+// tree.
+type Runner struct {
+	cfg    Config
+	logger *logr.Logger
+}
+
+// NewContainerRunner builds a Runner for the check-container plugin.
+func NewContainerRunner(cfg Config, opts ...Option) *Runner {
+	cfg.pluginName = "check-container"
+	return newRunner(cfg, opts...)
+}
+
+// NewOperatorRunner builds a Runner for the check-operator plugin.
+func NewOperatorRunner(cfg Config, opts ...Option) *Runner {
+	cfg.pluginName = "check-operator"
+	return newRunner(cfg, opts...)
+}
+
+func newRunner(cfg Config, opts ...Option) *Runner {
+	r := &Runner{cfg: cfg}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// buildLogger returns the logger WithLogger supplied, or builds one from
+// Config.LogWriter/Config.LogLevel if no override was given.
+func (r *Runner) buildLogger() logr.Logger {
+	if r.logger != nil {
+		return *r.logger
+	}
+
+	l := logrus.New()
+	l.SetFormatter(&logrus.TextFormatter{DisableColors: true})
+	if r.cfg.LogWriter != nil {
+		l.SetOutput(r.cfg.LogWriter)
+	} else {
+		l.SetOutput(io.Discard)
+	}
+	if ll, err := logrus.ParseLevel(r.cfg.LogLevel); err == nil {
+		l.SetLevel(ll)
+	}
+
+	return logrusr.New(l)
+}
+
+// Run initializes, executes, and (if Config.Submit is set) submits the
+// Runner's plugin, returning its results for the caller to format and persist
+// however it sees fit.
+func (r *Runner) Run(ctx context.Context) (types.Results, error) {
+	logger := r.buildLogger()
+	ctx = logr.NewContext(ctx, logger.WithValues("emitter", "plugin"))
+
+	artifactsWriter, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(r.cfg.ArtifactsDir))
+	if err != nil {
+		return types.Results{}, err
+	}
+	ctx = artifacts.ContextWithWriter(ctx, artifactsWriter)
+
+	pl, ok := plugin.RegisteredPlugins()[r.cfg.pluginName]
+	if !ok {
+		return types.Results{}, fmt.Errorf("no plugin registered under name %q", r.cfg.pluginName)
+	}
+
+	logger.Info("Calling plugin", "name", pl.Name(), "version", pl.Version())
+
+	// Plugins still expect a *viper.Viper in Init, so build one from the
+	// typed Config rather than changing the plugin.Plugin interface.
+	pluginConfig := spfviper.New()
+	pluginConfig.Set("artifacts", r.cfg.ArtifactsDir)
+	pluginConfig.Set("loglevel", r.cfg.LogLevel)
+	pluginConfig.Set("submit", r.cfg.Submit)
+
+	if err := pl.Init(ctx, pluginConfig, r.cfg.PluginArgs); err != nil {
+		return types.Results{}, fmt.Errorf("unable to initialize plugin: %w", err)
+	}
+
+	if err := pl.ExecuteChecks(ctx); err != nil {
+		return types.Results{}, fmt.Errorf("unable to execute checks: %w", err)
+	}
+
+	results := pl.Results(ctx)
+
+	if r.cfg.Submit {
+		if err := pl.Submit(ctx); err != nil {
+			return results, fmt.Errorf("unable to call plugin submission: %w", err)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,62 @@
+package lib
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-logr/logr"
+)
+
+func TestBuildLoggerUsesWithLoggerOverride(t *testing.T) {
+	override := logr.Discard()
+
+	r := newRunner(Config{}, WithLogger(override))
+
+	got := r.buildLogger()
+	if got != override {
+		t.Errorf("buildLogger() did not return the logger passed to WithLogger")
+	}
+}
+
+func TestBuildLoggerWritesToConfiguredWriter(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRunner(Config{LogWriter: &buf, LogLevel: "info"})
+
+	r.buildLogger().Info("hello")
+
+	if buf.Len() == 0 {
+		t.Error("buildLogger() did not write log output to Config.LogWriter")
+	}
+}
+
+func TestBuildLoggerDiscardsByDefault(t *testing.T) {
+	r := newRunner(Config{LogLevel: "info"})
+
+	// A nil LogWriter should discard logs rather than writing to os.Stdout/Stderr
+	// out from under an embedder that didn't ask for console output.
+	r.buildLogger().Info("hello")
+}
+
+func TestBuildLoggerRespectsLogLevel(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRunner(Config{LogWriter: &buf, LogLevel: "error"})
+
+	r.buildLogger().Info("hello")
+
+	if buf.Len() != 0 {
+		t.Errorf("buildLogger() wrote an Info line at LogLevel \"error\": %q", buf.String())
+	}
+}
+
+func TestBuildLoggerFallsBackOnInvalidLogLevel(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRunner(Config{LogWriter: &buf, LogLevel: "not-a-level"})
+
+	// logrus.ParseLevel fails for an invalid level, so buildLogger should leave
+	// logrus's own default level in place rather than erroring out.
+	r.buildLogger().Info("hello")
+
+	if buf.Len() == 0 {
+		t.Error("buildLogger() produced no output for an invalid LogLevel, want logrus's default level")
+	}
+}
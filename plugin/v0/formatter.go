@@ -0,0 +1,58 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/opdev/knex/types"
+)
+
+// FormatterFunc renders a set of check results into a byte payload, e.g. for
+// writing to a results file.
+type FormatterFunc = func(context.Context, types.Results) (response []byte, formattingError error)
+
+var (
+	formatterMu sync.RWMutex
+
+	// formatters holds every FormatterFunc selectable via knex's --format
+	// flag, keyed by name. Plugin authors add to this with RegisterFormatter.
+	formatters = map[string]FormatterFunc{}
+
+	// formatterExtensions maps a formatter name to the extension used for the
+	// results file it produces, e.g. "junitxml" -> "xml".
+	formatterExtensions = map[string]string{}
+)
+
+// RegisterFormatter adds a FormatterFunc under name, along with the results
+// file extension it should be written with, so it becomes selectable via
+// knex's --format/PFLT_FORMAT flag. Plugin authors can call this from an
+// init() to ship domain-specific result schemas without depending on knex's
+// command-layer packages.
+func RegisterFormatter(name, ext string, fn FormatterFunc) error {
+	formatterMu.Lock()
+	defer formatterMu.Unlock()
+
+	if _, ok := formatters[name]; ok {
+		return fmt.Errorf("a formatter named %q is already registered", name)
+	}
+
+	formatters[name] = fn
+	formatterExtensions[name] = ext
+
+	return nil
+}
+
+// FormatterFor resolves the FormatterFunc and results-file extension
+// registered under name.
+func FormatterFor(name string) (FormatterFunc, string, error) {
+	formatterMu.RLock()
+	defer formatterMu.RUnlock()
+
+	fn, ok := formatters[name]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown result format %q", name)
+	}
+
+	return fn, formatterExtensions[name], nil
+}
@@ -0,0 +1,195 @@
+// Package grpc implements an out-of-process plugin transport so third
+// parties can ship certification checks as standalone binaries in any
+// language, alongside the in-tree plugin.Plugin implementations linked
+// directly into the knex binary. The wire contract is defined in
+// plugin.proto; pluginrpc is its generated client/server code.
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"github.com/go-logr/logr"
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/opdev/knex/plugin/v0/grpc/pluginrpc"
+	"github.com/opdev/knex/types"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+)
+
+// Handshake is shared by knex and every out-of-process plugin binary so both
+// sides agree they're speaking the same protocol version before any RPCs are
+// made.
+var Handshake = goplugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "KNEX_PLUGIN",
+	MagicCookieValue: "v0",
+}
+
+// pluginMap is required by go-plugin's handshake; knex only ever dispenses
+// the single "plugin" entry from a child process.
+var pluginMap = map[string]goplugin.Plugin{
+	"plugin": &grpcPlugin{},
+}
+
+// NewGRPCClient launches the executable at path as a child process and
+// returns a Client that forwards Init/ExecuteChecks/Results/Submit calls to
+// it over gRPC, so run() can drive it identically to a compiled-in plugin.
+// Client satisfies plugin.Plugin, but callers get the concrete type back so
+// they can Close it once they know whether it'll actually be used.
+func NewGRPCClient(path string) (*Client, error) {
+	client := goplugin.NewClient(&goplugin.ClientConfig{
+		HandshakeConfig:  Handshake,
+		Plugins:          pluginMap,
+		Cmd:              exec.Command(path),
+		AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolGRPC},
+	})
+
+	rpcClient, err := client.Client()
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("unable to start plugin %s: %w", path, err)
+	}
+
+	raw, err := rpcClient.Dispense("plugin")
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("unable to dispense plugin %s: %w", path, err)
+	}
+
+	p, ok := raw.(*Client)
+	if !ok {
+		client.Kill()
+		return nil, fmt.Errorf("plugin %s did not return a gRPC plugin client", path)
+	}
+
+	p.process = client
+
+	return p, nil
+}
+
+// grpcPlugin satisfies go-plugin's plugin.GRPCPlugin so the framework knows
+// how to wire a grpc.ClientConn up to our PluginServiceClient. knex only
+// consumes plugins, so GRPCServer is unused.
+type grpcPlugin struct {
+	goplugin.NetRPCUnsupportedPlugin
+}
+
+func (p *grpcPlugin) GRPCClient(_ context.Context, _ *goplugin.GRPCBroker, conn *grpc.ClientConn) (interface{}, error) {
+	return &Client{client: pluginrpc.NewPluginServiceClient(conn)}, nil
+}
+
+func (p *grpcPlugin) GRPCServer(_ *goplugin.GRPCBroker, _ *grpc.Server) error {
+	return fmt.Errorf("knex does not serve plugins over gRPC, only consumes them")
+}
+
+// Client adapts a PluginServiceClient to knex's in-process
+// plugin.Plugin interface.
+type Client struct {
+	client  pluginrpc.PluginServiceClient
+	process *goplugin.Client
+}
+
+func (c *Client) Init(ctx context.Context, config *viper.Viper, args []string) error {
+	c.streamLogs(ctx)
+
+	_, err := c.client.Init(ctx, &pluginrpc.InitRequest{
+		Args:         args,
+		ArtifactsDir: config.GetString("artifacts"),
+	})
+
+	return err
+}
+
+// streamLogs opens StreamLogs once and forwards every line the plugin sends
+// back through the logr.Logger already attached to ctx (the same one run()
+// put there with logr.NewContext), so a gRPC plugin's log output ends up
+// alongside a compiled-in plugin's instead of nowhere. Opening the stream is
+// best-effort: a plugin that doesn't implement it yet shouldn't block
+// Init/ExecuteChecks, since logging isn't load-bearing for check results.
+func (c *Client) streamLogs(ctx context.Context) {
+	logger := logr.FromContext(ctx)
+
+	stream, err := c.client.StreamLogs(ctx, &pluginrpc.StreamLogsRequest{})
+	if err != nil {
+		logger.Error(err, "unable to open plugin log stream")
+		return
+	}
+
+	go func() {
+		for {
+			line, err := stream.Recv()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					logger.Error(err, "plugin log stream ended unexpectedly")
+				}
+				return
+			}
+
+			kvs := make([]interface{}, len(line.KeysAndValues))
+			for i, kv := range line.KeysAndValues {
+				kvs[i] = kv
+			}
+
+			if line.Level == "error" {
+				logger.Error(errors.New(line.Message), "plugin log", kvs...)
+			} else {
+				logger.Info(line.Message, kvs...)
+			}
+		}
+	}()
+}
+
+func (c *Client) ExecuteChecks(ctx context.Context) error {
+	_, err := c.client.ExecuteChecks(ctx, &pluginrpc.ExecuteChecksRequest{})
+	return err
+}
+
+func (c *Client) Results(ctx context.Context) types.Results {
+	resp, err := c.client.Results(ctx, &pluginrpc.ResultsRequest{})
+	if err != nil {
+		return types.Results{}
+	}
+
+	var results types.Results
+	if err := json.Unmarshal(resp.ResultsJson, &results); err != nil {
+		return types.Results{}
+	}
+
+	return results
+}
+
+func (c *Client) Submit(ctx context.Context) error {
+	_, err := c.client.Submit(ctx, &pluginrpc.SubmitRequest{})
+	return err
+}
+
+func (c *Client) Name() string {
+	resp, err := c.client.Name(context.Background(), &pluginrpc.NameRequest{})
+	if err != nil {
+		return ""
+	}
+
+	return resp.Name
+}
+
+func (c *Client) Version() string {
+	resp, err := c.client.Version(context.Background(), &pluginrpc.VersionRequest{})
+	if err != nil {
+		return ""
+	}
+
+	return resp.Version
+}
+
+// Close stops the child plugin process. Callers should defer this right
+// after a successful NewGRPCClient.
+func (c *Client) Close() {
+	if c.process != nil {
+		c.process.Kill()
+	}
+}
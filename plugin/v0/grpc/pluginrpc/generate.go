@@ -0,0 +1,8 @@
+// Package pluginrpc holds the generated gRPC client/server code for knex's
+// out-of-process plugin protocol, produced from ../plugin.proto by `make
+// generate` (or the protoc invocation below). Apart from this file, nothing
+// in this package is hand-written; edit ../plugin.proto and regenerate
+// instead of editing plugin.pb.go/plugin_grpc.pb.go directly.
+package pluginrpc
+
+//go:generate protoc -I .. --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ../plugin.proto
@@ -0,0 +1,113 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	goplugin "plugin"
+	"sync"
+
+	"github.com/opdev/knex/plugin/v0/grpc"
+)
+
+var registryMu sync.Mutex
+
+// ArtifactKindGoPlugin identifies an ArtifactMeta whose Path is a Go plugin
+// (.so) binary that can be loaded with plugin.Open.
+const ArtifactKindGoPlugin = "goplugin"
+
+// ArtifactKindGRPCPlugin identifies an ArtifactMeta whose Path is a signed,
+// standalone executable that speaks knex's out-of-process gRPC plugin
+// protocol (plugin/v0/grpc), launched the same way discoverGRPCPlugins
+// launches one found in --plugin-dir.
+const ArtifactKindGRPCPlugin = "grpcplugin"
+
+// ArtifactMeta describes a plugin artifact that was pulled from an OCI
+// registry: enough information to load and register it without re-fetching.
+type ArtifactMeta struct {
+	// Name is the plugin's invocation name, e.g. "check-container".
+	Name string
+	// Path is the local path to the artifact, relative to the directory it
+	// was pulled into unless it's already absolute.
+	Path string
+	// Kind identifies what Path points to (one of the ArtifactKind*
+	// constants). OCI content-addressed blobs carry no file extension, so
+	// callers must classify the artifact themselves (e.g. from the layer's
+	// media type) rather than have RegisterFromArtifact guess from Path.
+	Kind string
+}
+
+// RegisterFromArtifact loads the plugin artifact described by meta and adds
+// it to RegisteredPlugins() so it's available to the next "knex run"
+// invocation, alongside plugins that were compiled in.
+//
+// ArtifactKindGoPlugin and ArtifactKindGRPCPlugin are supported.
+func RegisterFromArtifact(dir string, meta ArtifactMeta) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	path := meta.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	switch meta.Kind {
+	case ArtifactKindGoPlugin:
+		return registerGoPlugin(meta.Name, path)
+	case ArtifactKindGRPCPlugin:
+		return registerGRPCPlugin(meta.Name, path)
+	default:
+		return fmt.Errorf("unsupported plugin artifact kind %q", meta.Kind)
+	}
+}
+
+// registerGoPlugin loads path as a Go plugin (.so) and registers its
+// exported Plugin symbol under name.
+func registerGoPlugin(name, path string) error {
+	p, err := goplugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("unable to open plugin artifact %s: %w", path, err)
+	}
+
+	sym, err := p.Lookup("Plugin")
+	if err != nil {
+		return fmt.Errorf("plugin artifact %s does not export a Plugin symbol: %w", path, err)
+	}
+
+	pl, ok := sym.(Plugin)
+	if !ok {
+		return fmt.Errorf("plugin artifact %s's Plugin symbol does not satisfy plugin.Plugin", path)
+	}
+
+	RegisteredPlugins()[name] = pl
+
+	return nil
+}
+
+// registerGRPCPlugin launches path as an out-of-process gRPC plugin and
+// registers the resulting client under name. Blobs land in the OCI content
+// store without the execute bit, so it's restored here before exec.Command
+// is handed the path.
+func registerGRPCPlugin(name, path string) error {
+	if err := os.Chmod(path, 0o755); err != nil {
+		return fmt.Errorf("unable to mark plugin artifact %s executable: %w", path, err)
+	}
+
+	client, err := grpc.NewGRPCClient(path)
+	if err != nil {
+		return fmt.Errorf("unable to start plugin artifact %s: %w", path, err)
+	}
+
+	RegisteredPlugins()[name] = client
+
+	return nil
+}
+
+// Unregister removes name from RegisteredPlugins(), e.g. after `knex plugin
+// remove` deletes its cached artifact.
+func Unregister(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	delete(RegisteredPlugins(), name)
+}